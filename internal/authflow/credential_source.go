@@ -0,0 +1,171 @@
+package authflow
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cli/cli/v2/internal/config"
+	"github.com/cli/cli/v2/internal/keyring"
+)
+
+// ErrNotFound is returned by CredentialSource.Token when that particular source has nothing to
+// offer for the given hostname/username, as opposed to some other failure (e.g. a file that
+// exists but can't be read).
+var ErrNotFound = errors.New("no credential found for this source")
+
+// CredentialSource resolves an auth token for a host/user pair from a single origin: the OS
+// keyring, the insecure oauth_token stored in hosts.yml, an environment variable, or an external
+// file. `gh auth status` reports which source won so that users troubleshooting "why is gh using
+// the wrong account" don't have to guess.
+type CredentialSource interface {
+	// Name is the human-readable label shown in `gh auth status --sources` and in the
+	// per-account "Source:" line, e.g. "keyring" or "GH_TOKEN env".
+	Name() string
+	// Token resolves the token for hostname/username from this source. It returns
+	// ErrNotFound (wrapped or bare) when this source simply has nothing for this
+	// hostname/username, which callers should treat as "try the next source" rather than
+	// a hard failure.
+	Token(hostname, username string) (string, error)
+	// Available reports whether this source is usable at all in the current environment,
+	// independent of any specific hostname/username (e.g. whether an env var is set).
+	Available() bool
+}
+
+// DefaultChain returns the credential sources gh checks, in priority order: an explicit
+// GH_TOKEN/GITHUB_TOKEN env var wins (matching historical behavior), followed by GH_TOKEN_FILE,
+// the OS keyring, and finally the insecure oauth_token stored directly in hosts.yml.
+func DefaultChain(cfg config.Config) []CredentialSource {
+	return []CredentialSource{
+		envCredentialSource{},
+		fileCredentialSource{},
+		keyringCredentialSource{},
+		configCredentialSource{cfg: cfg},
+	}
+}
+
+// TokenForUser resolves hostname/username's token by walking DefaultChain(cfg) and returns it
+// together with the name of the source that supplied it. `gh auth status` uses it both for the
+// token it probes with and for the source it reports. Call sites that read a token through
+// authCfg.TokenForUser directly (API client construction, the git credential helper) do not go
+// through this chain, so GH_TOKEN_FILE has no effect on them.
+func TokenForUser(cfg config.Config, hostname, username string) (token, source string, err error) {
+	return Resolve(DefaultChain(cfg), hostname, username)
+}
+
+// Resolve walks sources in priority order and returns the token and source name of the first one
+// that has a token for hostname/username.
+func Resolve(sources []CredentialSource, hostname, username string) (token, source string, err error) {
+	for _, s := range sources {
+		if !s.Available() {
+			continue
+		}
+
+		token, err := s.Token(hostname, username)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				continue
+			}
+			return "", "", fmt.Errorf("%s: %w", s.Name(), err)
+		}
+
+		return token, s.Name(), nil
+	}
+
+	return "", "", fmt.Errorf("no credential found for %s", hostname)
+}
+
+type envCredentialSource struct{}
+
+func (envCredentialSource) Name() string { return "GH_TOKEN env" }
+
+func (envCredentialSource) Available() bool {
+	return os.Getenv("GH_TOKEN") != "" || os.Getenv("GITHUB_TOKEN") != ""
+}
+
+func (envCredentialSource) Token(hostname, username string) (string, error) {
+	if token := os.Getenv("GH_TOKEN"); token != "" {
+		return token, nil
+	}
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		return token, nil
+	}
+	return "", ErrNotFound
+}
+
+// fileCredentialSource reads a token from a file named by GH_TOKEN_FILE, following the pattern
+// used by other CLIs for secret-file auth (e.g. DOCKER_CONFIG-style credential files).
+type fileCredentialSource struct{}
+
+func (fileCredentialSource) Name() string {
+	if path := os.Getenv("GH_TOKEN_FILE"); path != "" {
+		return path
+	}
+	return "GH_TOKEN_FILE"
+}
+
+func (fileCredentialSource) Available() bool {
+	path := os.Getenv("GH_TOKEN_FILE")
+	if path == "" {
+		return false
+	}
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func (fileCredentialSource) Token(hostname, username string) (string, error) {
+	path := os.Getenv("GH_TOKEN_FILE")
+	if path == "" {
+		return "", ErrNotFound
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	token := strings.TrimSpace(string(contents))
+	if token == "" {
+		return "", ErrNotFound
+	}
+
+	return token, nil
+}
+
+type keyringCredentialSource struct{}
+
+func (keyringCredentialSource) Name() string { return "keyring" }
+
+func (keyringCredentialSource) Available() bool { return true }
+
+func (keyringCredentialSource) Token(hostname, username string) (string, error) {
+	token, err := keyring.Get(keyringServiceName(hostname), username)
+	if err != nil || token == "" {
+		return "", ErrNotFound
+	}
+	return token, nil
+}
+
+type configCredentialSource struct {
+	cfg config.Config
+}
+
+func (configCredentialSource) Name() string {
+	return filepath.Join(config.ConfigDir(), "hosts.yml")
+}
+
+func (configCredentialSource) Available() bool { return true }
+
+func (s configCredentialSource) Token(hostname, username string) (string, error) {
+	token, _ := s.cfg.Authentication().TokenForUser(hostname, username)
+	if token == "" {
+		return "", ErrNotFound
+	}
+	return token, nil
+}
+
+func keyringServiceName(hostname string) string {
+	return "gh:" + hostname
+}
@@ -0,0 +1,126 @@
+package authflow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cli/cli/v2/internal/config"
+	"github.com/cli/cli/v2/internal/keyring"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvCredentialSource(t *testing.T) {
+	t.Run("unavailable when unset", func(t *testing.T) {
+		var s envCredentialSource
+		require.False(t, s.Available())
+	})
+
+	t.Run("GH_TOKEN takes priority over GITHUB_TOKEN", func(t *testing.T) {
+		t.Setenv("GH_TOKEN", "gh-token-value")
+		t.Setenv("GITHUB_TOKEN", "github-token-value")
+
+		var s envCredentialSource
+		require.True(t, s.Available())
+
+		token, err := s.Token("github.com", "monalisa")
+		require.NoError(t, err)
+		require.Equal(t, "gh-token-value", token)
+	})
+
+	t.Run("falls back to GITHUB_TOKEN", func(t *testing.T) {
+		t.Setenv("GITHUB_TOKEN", "github-token-value")
+
+		var s envCredentialSource
+		token, err := s.Token("github.com", "monalisa")
+		require.NoError(t, err)
+		require.Equal(t, "github-token-value", token)
+	})
+}
+
+func TestFileCredentialSource(t *testing.T) {
+	t.Run("unavailable when GH_TOKEN_FILE unset", func(t *testing.T) {
+		var s fileCredentialSource
+		require.False(t, s.Available())
+	})
+
+	t.Run("reads token from file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "token")
+		require.NoError(t, os.WriteFile(path, []byte("file-token-value\n"), 0o600))
+		t.Setenv("GH_TOKEN_FILE", path)
+
+		var s fileCredentialSource
+		require.True(t, s.Available())
+		require.Equal(t, path, s.Name())
+
+		token, err := s.Token("github.com", "monalisa")
+		require.NoError(t, err)
+		require.Equal(t, "file-token-value", token)
+	})
+
+	t.Run("unavailable when file doesn't exist", func(t *testing.T) {
+		t.Setenv("GH_TOKEN_FILE", filepath.Join(t.TempDir(), "missing"))
+
+		var s fileCredentialSource
+		require.False(t, s.Available())
+	})
+}
+
+func TestKeyringCredentialSource(t *testing.T) {
+	keyring.MockInit()
+	require.NoError(t, keyring.Set("gh:github.com", "monalisa", "keyring-token-value"))
+
+	var s keyringCredentialSource
+	require.True(t, s.Available())
+
+	token, err := s.Token("github.com", "monalisa")
+	require.NoError(t, err)
+	require.Equal(t, "keyring-token-value", token)
+
+	_, err = s.Token("github.com", "someone-else")
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestConfigCredentialSource(t *testing.T) {
+	cfg, _ := config.NewIsolatedTestConfig(t)
+	_, err := cfg.Authentication().Login("github.com", "monalisa", "config-token-value", "https", false)
+	require.NoError(t, err)
+
+	s := configCredentialSource{cfg: cfg}
+	require.True(t, s.Available())
+
+	token, err := s.Token("github.com", "monalisa")
+	require.NoError(t, err)
+	require.Equal(t, "config-token-value", token)
+
+	_, err = s.Token("github.com", "someone-else")
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestResolve(t *testing.T) {
+	keyring.MockInit()
+	cfg, _ := config.NewIsolatedTestConfig(t)
+	_, err := cfg.Authentication().Login("github.com", "monalisa", "config-token-value", "https", false)
+	require.NoError(t, err)
+
+	t.Run("prefers env over keyring and config", func(t *testing.T) {
+		t.Setenv("GH_TOKEN", "env-token-value")
+
+		token, source, err := Resolve(DefaultChain(cfg), "github.com", "monalisa")
+		require.NoError(t, err)
+		require.Equal(t, "env-token-value", token)
+		require.Equal(t, "GH_TOKEN env", source)
+	})
+
+	t.Run("falls back to config when nothing else matches", func(t *testing.T) {
+		token, source, err := Resolve(DefaultChain(cfg), "github.com", "monalisa")
+		require.NoError(t, err)
+		require.Equal(t, "config-token-value", token)
+		require.Equal(t, filepath.Join(config.ConfigDir(), "hosts.yml"), source)
+	})
+
+	t.Run("errors when nothing has a token", func(t *testing.T) {
+		_, _, err := Resolve(DefaultChain(cfg), "github.com", "nobody")
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,185 @@
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/cli/cli/v2/internal/ghinstance"
+	"github.com/cli/cli/v2/pkg/iostreams"
+)
+
+// orgStatus is the SSO/IP allow list outcome of probing a single org for a single account.
+type orgStatus struct {
+	org            string
+	ssoAuthorized  bool
+	ssoURL         string
+	ipAllowListHit bool
+	err            error
+}
+
+// ipAllowListMessage is the substring GitHub's API returns in the error body when a request is
+// denied because the requester's IP isn't on an organization's allow list.
+const ipAllowListMessage = "organization has enabled or enforced IP allow list"
+
+// probeOrgs checks SSO authorization and IP allow list status for an account against each of the
+// given orgs. Membership is established first via GET /user/orgs; an org the account doesn't
+// belong to is skipped rather than reported on, since GET /orgs/{org} is a public endpoint that
+// would otherwise happily return 200 for orgs the token-holder has no relationship to.
+func probeOrgs(ctx context.Context, httpClient *http.Client, hostname, token string, orgs []string) []orgStatus {
+	if len(orgs) == 0 {
+		return nil
+	}
+
+	members, err := fetchUserOrgs(ctx, httpClient, hostname, token)
+	if err != nil {
+		results := make([]orgStatus, 0, len(orgs))
+		for _, org := range orgs {
+			results = append(results, orgStatus{org: org, err: err})
+		}
+		return results
+	}
+
+	results := make([]orgStatus, 0, len(orgs))
+	for _, org := range orgs {
+		if !members[strings.ToLower(org)] {
+			continue
+		}
+		results = append(results, probeOrg(ctx, httpClient, hostname, token, org))
+	}
+
+	return results
+}
+
+// userOrg is the subset of the GET /user/orgs response fields probeOrgs needs.
+type userOrg struct {
+	Login string `json:"login"`
+}
+
+// fetchUserOrgs returns the set of org logins (lowercased) the token's account is a member of.
+func fetchUserOrgs(ctx context.Context, httpClient *http.Client, hostname, token string) (map[string]bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ghinstance.RESTPrefix(hostname)+"user/orgs", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "token "+token)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to list organization memberships: %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	var orgs []userOrg
+	if err := json.NewDecoder(resp.Body).Decode(&orgs); err != nil {
+		return nil, err
+	}
+
+	members := make(map[string]bool, len(orgs))
+	for _, o := range orgs {
+		members[strings.ToLower(o.Login)] = true
+	}
+	return members, nil
+}
+
+// probeOrg checks SSO authorization and IP allow list status for a single org the account is
+// already known to be a member of.
+func probeOrg(ctx context.Context, httpClient *http.Client, hostname, token, org string) orgStatus {
+	status := orgStatus{org: org}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ghinstance.RESTPrefix(hostname)+"orgs/"+org, nil)
+	if err != nil {
+		status.err = err
+		return status
+	}
+	req.Header.Set("Authorization", "token "+token)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		status.err = err
+		return status
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusForbidden {
+		body, _ := io.ReadAll(resp.Body)
+		if strings.Contains(string(body), ipAllowListMessage) {
+			status.ipAllowListHit = true
+			return status
+		}
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return status
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		status.err = fmt.Errorf("failed to check org status: %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+		return status
+	}
+
+	if sso := resp.Header.Get("X-GitHub-SSO"); strings.HasPrefix(sso, "required") {
+		status.ssoURL = parseSSOURL(sso)
+	} else {
+		status.ssoAuthorized = true
+	}
+
+	return status
+}
+
+// parseSSOURL extracts the authorization URL from an "X-GitHub-SSO: required; url=..." header.
+func parseSSOURL(header string) string {
+	for _, part := range strings.Split(header, ";") {
+		part = strings.TrimSpace(part)
+		if u, ok := strings.CutPrefix(part, "url="); ok {
+			return u
+		}
+	}
+	return ""
+}
+
+// formatOrgStatus renders the SSO/IP-allow-list report lines for a single account: first any
+// probe errors, then a single combined "SSO authorized for" line, then one line per org needing
+// SSO authorization, then one line per org that denied the request via its IP allow list.
+func formatOrgStatus(cs *iostreams.ColorScheme, hostname string, orgs []orgStatus) []string {
+	var lines []string
+
+	for _, o := range orgs {
+		if o.err != nil {
+			lines = append(lines, fmt.Sprintf("%s Could not check org status for %s: %s", cs.FailureIcon(), o.org, o.err))
+		}
+	}
+
+	var authorized []string
+	for _, o := range orgs {
+		if o.ssoAuthorized {
+			authorized = append(authorized, o.org)
+		}
+	}
+	if len(authorized) > 0 {
+		lines = append(lines, fmt.Sprintf("%s SSO authorized for: %s", cs.SuccessIcon(), strings.Join(authorized, ", ")))
+	}
+
+	for _, o := range orgs {
+		if o.ssoURL != "" {
+			lines = append(lines, fmt.Sprintf(
+				"%s Token needs SSO authorization for %s (run: gh auth refresh -h %s then visit %s)",
+				cs.FailureIcon(), o.org, hostname, o.ssoURL))
+		}
+	}
+
+	for _, o := range orgs {
+		if o.ipAllowListHit {
+			lines = append(lines, fmt.Sprintf("%s Request denied by IP allow list", cs.FailureIcon()))
+		}
+	}
+
+	return lines
+}
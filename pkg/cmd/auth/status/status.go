@@ -0,0 +1,493 @@
+package status
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cli/cli/v2/api"
+	"github.com/cli/cli/v2/internal/authflow"
+	"github.com/cli/cli/v2/internal/config"
+	"github.com/cli/cli/v2/pkg/cmdutil"
+	"github.com/cli/cli/v2/pkg/iostreams"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+)
+
+// probeConcurrencyLimit bounds how many host/account probes run at once so that a user
+// authenticated against many GHES instances doesn't open an unbounded number of connections.
+const probeConcurrencyLimit = 8
+
+var jsonFields = []string{
+	"hostname",
+	"user",
+	"active",
+	"gitProtocol",
+	"tokenSource",
+	"tokenLastEightChars",
+	"scopes",
+	"hasMinimumScopes",
+	"error",
+	"orgs",
+}
+
+type StatusOptions struct {
+	HttpClient func() (*http.Client, error)
+	IO         *iostreams.IOStreams
+	Config     func() (config.Config, error)
+	Exporter   cmdutil.Exporter
+
+	Hostname  string
+	ShowToken bool
+	Timeout   time.Duration
+	Orgs      []string
+	Sources   bool
+}
+
+func NewCmdStatus(f *cmdutil.Factory, runF func(*StatusOptions) error) *cobra.Command {
+	opts := &StatusOptions{
+		HttpClient: f.HttpClient,
+		IO:         f.IOStreams,
+		Config:     f.Config,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Args:  cobra.ExactArgs(0),
+		Short: "View authentication status",
+		Long: heredoc.Docf(`Verifies and displays information about your authentication state.
+
+			This command will test your authentication state for each GitHub host that gh knows about
+			and report on any issues. When more than one account is logged in to a host, every account
+			is listed with the currently active one marked "(active)".
+
+			Accounts are probed concurrently across hosts, so a single unresponsive host doesn't stall
+			the others. Use %[1]s--timeout%[1]s to bound how long an individual probe is allowed to take.
+
+			Every account whose check succeeds reports a "Source:" line naming where its token came
+			from (an env var, a file, the OS keyring, or hosts.yml); an account whose check fails has
+			no "Source:" line. Pass %[1]s--sources%[1]s to see the full credential resolution chain gh
+			checked to get there.
+		`, "`"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if runF != nil {
+				return runF(opts)
+			}
+
+			return statusRun(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Hostname, "hostname", "h", "", "Check a specific hostname's auth status")
+	cmd.Flags().BoolVarP(&opts.ShowToken, "show-token", "t", false, "Display the auth token")
+	cmd.Flags().DurationVar(&opts.Timeout, "timeout", 3*time.Second, "Timeout for each host/account probe, e.g. '5s'")
+	cmd.Flags().StringArrayVar(&opts.Orgs, "org", nil, "Check SSO and IP allow list status for ORG (can be used multiple times)")
+	cmd.Flags().BoolVar(&opts.Sources, "sources", false, "Print the credential resolution chain for each account and which entry won")
+	cmdutil.AddJSONFlags(cmd, &opts.Exporter, jsonFields)
+
+	return cmd
+}
+
+// accountStatus is the outcome of probing a single logged-in account.
+type accountStatus struct {
+	hostname    string
+	username    string
+	active      bool
+	token       string
+	tokenSource string
+	protocol    string
+	scopes      string
+	hasScopes   bool
+	err         error
+	latency     time.Duration
+	orgs        []orgStatus
+}
+
+func statusRun(opts *StatusOptions) error {
+	if opts.Sources && opts.Exporter != nil {
+		return cmdutil.FlagErrorf("`--sources` does not support `--json`; the per-account \"Source:\" field already carries the winning source")
+	}
+
+	cfg, err := opts.Config()
+	if err != nil {
+		return err
+	}
+
+	authCfg := cfg.Authentication()
+	stdout := opts.IO.Out
+	stderr := opts.IO.ErrOut
+	cs := opts.IO.ColorScheme()
+
+	hostnames := authCfg.Hosts()
+	if len(hostnames) == 0 {
+		fmt.Fprintf(stderr,
+			"You are not logged into any GitHub hosts. Run %s to authenticate.\n", cs.Bold("gh auth login"))
+		return cmdutil.SilentError
+	}
+
+	httpClient, err := opts.HttpClient()
+	if err != nil {
+		return err
+	}
+
+	type job struct {
+		hostname string
+		username string
+	}
+
+	var includedHosts []string
+	var jobs []job
+	for _, hostname := range hostnames {
+		if opts.Hostname != "" && opts.Hostname != hostname {
+			continue
+		}
+		includedHosts = append(includedHosts, hostname)
+		for _, username := range usersForHost(authCfg, hostname) {
+			jobs = append(jobs, job{hostname: hostname, username: username})
+		}
+	}
+
+	if opts.Hostname != "" && len(includedHosts) == 0 {
+		fmt.Fprintf(stderr, "Hostname %q not found among authenticated GitHub hosts\n", opts.Hostname)
+		return cmdutil.SilentError
+	}
+
+	g, ctx := errgroup.WithContext(context.Background())
+	g.SetLimit(probeConcurrencyLimit)
+
+	results := make([]accountStatus, len(jobs))
+	for i, j := range jobs {
+		i, j := i, j
+		g.Go(func() error {
+			probeCtx := ctx
+			if opts.Timeout > 0 {
+				var cancel context.CancelFunc
+				probeCtx, cancel = context.WithTimeout(ctx, opts.Timeout)
+				defer cancel()
+			}
+			results[i] = probeAccount(probeCtx, httpClient, cfg, j.hostname, j.username, opts.Orgs)
+			return nil
+		})
+	}
+	// g.Go above never returns a non-nil error: per-account failures, including a probe that
+	// runs past opts.Timeout, are captured on the result instead, so that one bad probe doesn't
+	// cancel the others.
+	_ = g.Wait()
+
+	byHost := make(map[string][]accountStatus, len(includedHosts))
+	for _, r := range results {
+		byHost[r.hostname] = append(byHost[r.hostname], r)
+	}
+
+	if opts.Exporter != nil {
+		return writeJSON(opts.Exporter, opts.IO, includedHosts, byHost)
+	}
+
+	var failed bool
+	firstHost := true
+	for _, hostname := range includedHosts {
+		accounts := byHost[hostname]
+		sort.SliceStable(accounts, func(i, j int) bool {
+			if accounts[i].active != accounts[j].active {
+				return accounts[i].active
+			}
+			return accounts[i].username < accounts[j].username
+		})
+
+		if !firstHost {
+			fmt.Fprint(stdout, "\n")
+		}
+		firstHost = false
+		fmt.Fprintf(stdout, "%s\n", hostname)
+
+		showActive := len(accounts) > 1
+		firstAccount := true
+		for _, account := range accounts {
+			if !firstAccount {
+				fmt.Fprint(stdout, "\n")
+			}
+			firstAccount = false
+
+			ok := reportAccountStatus(stdout, cs, account, showActive, opts.ShowToken)
+			if !ok {
+				failed = true
+			}
+
+			if opts.Sources {
+				reportCredentialSources(stdout, cfg, account.hostname, account.username)
+			}
+		}
+	}
+
+	if failed {
+		return cmdutil.SilentError
+	}
+
+	return nil
+}
+
+// jsonAccount is the --json representation of a single probed account.
+type jsonAccount struct {
+	Hostname            string          `json:"hostname"`
+	User                string          `json:"user"`
+	Active              bool            `json:"active"`
+	GitProtocol         string          `json:"gitProtocol"`
+	TokenSource         string          `json:"tokenSource"`
+	TokenLastEightChars string          `json:"tokenLastEightChars"`
+	Scopes              []string        `json:"scopes"`
+	HasMinimumScopes    bool            `json:"hasMinimumScopes"`
+	Error               string          `json:"error"`
+	Orgs                []jsonOrgStatus `json:"orgs"`
+}
+
+// jsonOrgStatus is the --json representation of a single org probed via --org.
+type jsonOrgStatus struct {
+	Org            string `json:"org"`
+	SSOAuthorized  bool   `json:"ssoAuthorized"`
+	SSOURL         string `json:"ssoUrl"`
+	IPAllowListHit bool   `json:"ipAllowListHit"`
+	Error          string `json:"error"`
+}
+
+func (a *jsonAccount) ExportData(fields []string) map[string]interface{} {
+	return cmdutil.StructExportData(a, fields)
+}
+
+func writeJSON(exporter cmdutil.Exporter, ios *iostreams.IOStreams, includedHosts []string, byHost map[string][]accountStatus) error {
+	var accounts []*jsonAccount
+	for _, hostname := range includedHosts {
+		hostAccounts := byHost[hostname]
+		sort.SliceStable(hostAccounts, func(i, j int) bool {
+			if hostAccounts[i].active != hostAccounts[j].active {
+				return hostAccounts[i].active
+			}
+			return hostAccounts[i].username < hostAccounts[j].username
+		})
+
+		for _, a := range hostAccounts {
+			accounts = append(accounts, toJSONAccount(a))
+		}
+	}
+
+	data := make([]interface{}, len(accounts))
+	for i, a := range accounts {
+		data[i] = a
+	}
+
+	return exporter.Write(ios, data)
+}
+
+func toJSONAccount(a accountStatus) *jsonAccount {
+	errMsg := ""
+	if a.err != nil {
+		errMsg = a.err.Error()
+	}
+
+	var scopes []string
+	if a.scopes != "" {
+		for _, s := range strings.Split(a.scopes, ",") {
+			scopes = append(scopes, strings.TrimSpace(s))
+		}
+	}
+
+	tokenLast8 := a.token
+	if len(a.token) > 8 {
+		tokenLast8 = a.token[len(a.token)-8:]
+	}
+
+	var orgs []jsonOrgStatus
+	for _, o := range a.orgs {
+		orgErrMsg := ""
+		if o.err != nil {
+			orgErrMsg = o.err.Error()
+		}
+		orgs = append(orgs, jsonOrgStatus{
+			Org:            o.org,
+			SSOAuthorized:  o.ssoAuthorized,
+			SSOURL:         o.ssoURL,
+			IPAllowListHit: o.ipAllowListHit,
+			Error:          orgErrMsg,
+		})
+	}
+
+	return &jsonAccount{
+		Hostname:            a.hostname,
+		User:                a.username,
+		Active:              a.active,
+		GitProtocol:         a.protocol,
+		TokenSource:         a.tokenSource,
+		TokenLastEightChars: tokenLast8,
+		Scopes:              scopes,
+		HasMinimumScopes:    a.hasScopes && a.err == nil,
+		Error:               errMsg,
+		Orgs:                orgs,
+	}
+}
+
+// probeAccount checks a single account's token against its host and reports the result. It never
+// returns an error directly; failures are captured on the returned accountStatus so that one bad
+// probe doesn't prevent the others in the worker pool from completing or being rendered.
+//
+// The token itself is resolved through authflow.TokenForUser rather than authCfg.TokenForUser
+// directly, so that the tokenSource reported here always matches what `--sources` reports for
+// the same account; configCredentialSource (the last link in the chain) is what ultimately
+// consults authCfg.TokenForUser when no higher-priority source has a token.
+func probeAccount(ctx context.Context, httpClient *http.Client, cfg config.Config, hostname, username string, orgs []string) accountStatus {
+	start := time.Now()
+
+	authCfg := cfg.Authentication()
+	protocol := authCfg.GitProtocol(hostname, username)
+	active := username == authCfg.ActiveUser(hostname)
+
+	token, tokenSource, err := authflow.TokenForUser(cfg, hostname, username)
+	if err != nil {
+		return accountStatus{
+			hostname: hostname,
+			username: username,
+			active:   active,
+			protocol: protocol,
+			err:      err,
+			latency:  time.Since(start),
+		}
+	}
+
+	scopes, hasScopes, err := api.HeaderHasMinimumScopesContext(ctx, httpClient, hostname, token)
+
+	var orgResults []orgStatus
+	if err == nil && len(orgs) > 0 {
+		orgResults = probeOrgs(ctx, httpClient, hostname, token, orgs)
+	}
+
+	return accountStatus{
+		hostname:    hostname,
+		username:    username,
+		active:      active,
+		token:       token,
+		tokenSource: tokenSource,
+		protocol:    protocol,
+		scopes:      scopes,
+		hasScopes:   hasScopes,
+		err:         err,
+		latency:     time.Since(start),
+		orgs:        orgResults,
+	}
+}
+
+// usersForHost returns the usernames logged in to hostname with the currently active user listed
+// first, followed by the rest in a stable, deterministic order.
+func usersForHost(authCfg config.AuthConfig, hostname string) []string {
+	users := authCfg.UsersForHost(hostname)
+	active := authCfg.ActiveUser(hostname)
+
+	sort.SliceStable(users, func(i, j int) bool {
+		if users[i] == active {
+			return true
+		}
+		if users[j] == active {
+			return false
+		}
+		return users[i] < users[j]
+	})
+
+	return users
+}
+
+// reportAccountStatus prints the status of a single probed account and reports whether the
+// account is in good standing.
+func reportAccountStatus(w io.Writer, cs *iostreams.ColorScheme, a accountStatus, showActive, showToken bool) bool {
+	if a.err != nil {
+		var netErr net.Error
+		if errors.As(a.err, &netErr) && netErr.Timeout() || errors.Is(a.err, context.DeadlineExceeded) {
+			fmt.Fprintf(w, "  %s %s: timeout trying to connect to host\n", cs.FailureIcon(), a.hostname)
+			return false
+		}
+
+		var missingScopes *api.MissingScopesError
+		if errors.As(a.err, &missingScopes) {
+			fmt.Fprintf(w, "  %s %s: the token in %s is missing required scope '%s'\n", cs.FailureIcon(), a.hostname, a.tokenSource, missingScopes.MissingScope)
+			fmt.Fprintf(w, "  - To request missing scopes, run: gh auth refresh -h %s\n", a.hostname)
+			return false
+		}
+
+		fmt.Fprintf(w, "  %s %s: authentication failed\n", cs.FailureIcon(), a.hostname)
+		fmt.Fprintf(w, "  - The %s token in %s is invalid.\n", a.hostname, a.tokenSource)
+		fmt.Fprintf(w, "  - To re-authenticate, run: gh auth login -h %s\n", a.hostname)
+		fmt.Fprintf(w, "  - To forget about this host, run: gh auth logout -h %s\n", a.hostname)
+		return false
+	}
+
+	fmt.Fprintf(w, "  %s Logged in to %s as %s (%s)", cs.SuccessIcon(), a.hostname, cs.Bold(a.username), a.tokenSource)
+	if showActive && a.active {
+		fmt.Fprintf(w, " %s", cs.Bold("(active)"))
+	}
+	fmt.Fprint(w, "\n")
+
+	fmt.Fprintf(w, "  %s Git operations for %s configured to use %s protocol.\n", cs.SuccessIcon(), a.hostname, a.protocol)
+	fmt.Fprintf(w, "  %s Token: %s\n", cs.SuccessIcon(), displayToken(a.token, showToken))
+	fmt.Fprintf(w, "  Source: %s\n", a.tokenSource)
+
+	if scopesLine, ok := formatScopes(cs, a.scopes, a.hasScopes); ok {
+		fmt.Fprintf(w, "  %s\n", scopesLine)
+	}
+
+	for _, line := range formatOrgStatus(cs, a.hostname, a.orgs) {
+		fmt.Fprintf(w, "  %s\n", line)
+	}
+
+	return true
+}
+
+// reportCredentialSources prints the full credential resolution chain for an account under
+// --sources, one line per candidate source in priority order, with a checkmark next to whichever
+// one actually supplied the token gh is using (the same source named on the account's "Source:"
+// line when its check succeeds).
+func reportCredentialSources(w io.Writer, cfg config.Config, hostname, username string) {
+	chain := authflow.DefaultChain(cfg)
+	_, winner, err := authflow.TokenForUser(cfg, hostname, username)
+
+	fmt.Fprintf(w, "  Credential sources for %s:\n", username)
+	for _, source := range chain {
+		state := "not available"
+		if source.Available() {
+			state = "available"
+		}
+
+		marker := " "
+		if err == nil && source.Name() == winner {
+			marker = "✓"
+		}
+		fmt.Fprintf(w, "    %s %s (%s)\n", marker, source.Name(), state)
+	}
+}
+
+func displayToken(token string, showToken bool) string {
+	if showToken {
+		return token
+	}
+	for _, prefix := range []string{"gho_", "ghu_", "ghs_", "ghp_", "github_pat_"} {
+		if strings.HasPrefix(token, prefix) {
+			return prefix + "******"
+		}
+	}
+	return "******"
+}
+
+// formatScopes renders the "Token scopes" line from the raw X-Oauth-Scopes header value.
+// Server-to-server and fine-grained PAT tokens don't carry that header at all, in which case
+// api.HeaderHasMinimumScopesContext reports it back as hasScopes == false and the line is omitted.
+func formatScopes(cs *iostreams.ColorScheme, scopes string, hasScopes bool) (string, bool) {
+	if !hasScopes {
+		return "", false
+	}
+	if scopes == "" {
+		return fmt.Sprintf("%s Token scopes: none", cs.WarningIcon()), true
+	}
+	return fmt.Sprintf("%s Token scopes: %s", cs.SuccessIcon(), scopes), true
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/MakeNowJust/heredoc"
 	"github.com/cli/cli/v2/internal/config"
@@ -44,6 +45,27 @@ func Test_NewCmdStatus(t *testing.T) {
 				ShowToken: true,
 			},
 		},
+		{
+			name: "timeout set",
+			cli:  "--timeout 5s",
+			wants: StatusOptions{
+				Timeout: 5 * time.Second,
+			},
+		},
+		{
+			name: "org set multiple times",
+			cli:  "--org acme --org contoso",
+			wants: StatusOptions{
+				Orgs: []string{"acme", "contoso"},
+			},
+		},
+		{
+			name: "sources set",
+			cli:  "--sources",
+			wants: StatusOptions{
+				Sources: true,
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -71,6 +93,13 @@ func Test_NewCmdStatus(t *testing.T) {
 			assert.NoError(t, err)
 
 			assert.Equal(t, tt.wants.Hostname, gotOpts.Hostname)
+			if tt.wants.Timeout != 0 {
+				assert.Equal(t, tt.wants.Timeout, gotOpts.Timeout)
+			}
+			if len(tt.wants.Orgs) > 0 {
+				assert.Equal(t, tt.wants.Orgs, gotOpts.Orgs)
+			}
+			assert.Equal(t, tt.wants.Sources, gotOpts.Sources)
 		})
 	}
 }
@@ -79,6 +108,7 @@ func Test_statusRun(t *testing.T) {
 	tests := []struct {
 		name       string
 		opts       StatusOptions
+		env        map[string]string
 		httpStubs  func(*httpmock.Registry)
 		cfgStubs   func(config.Config)
 		wantErr    error
@@ -102,6 +132,33 @@ func Test_statusRun(t *testing.T) {
 			wantOut: heredoc.Doc(`
                 github.com
                   X github.com: timeout trying to connect to host
+            `),
+		},
+		{
+			name: "timeout cancels parallel probes",
+			opts: StatusOptions{
+				Timeout: 1 * time.Millisecond,
+			},
+			cfgStubs: func(c config.Config) {
+				login(t, c, "github.com", "monalisa", "abc123", "https")
+				login(t, c, "ghe.io", "monalisa-ghe", "abc123", "https")
+			},
+			httpStubs: func(reg *httpmock.Registry) {
+				// Both probes block until their own contexts are canceled by --timeout,
+				// then surface that cancellation the same way a slow host would.
+				blocked := func(req *http.Request) (*http.Response, error) {
+					<-req.Context().Done()
+					return nil, req.Context().Err()
+				}
+				reg.Register(httpmock.REST("GET", ""), blocked)
+				reg.Register(httpmock.REST("GET", "api/v3/"), blocked)
+			},
+			wantOut: heredoc.Doc(`
+                github.com
+                  X github.com: timeout trying to connect to host
+
+                ghe.io
+                  X ghe.io: timeout trying to connect to host
             `),
 		},
 		{
@@ -122,6 +179,7 @@ func Test_statusRun(t *testing.T) {
                   ✓ Logged in to ghe.io as monalisa-ghe (GH_CONFIG_DIR/hosts.yml)
                   ✓ Git operations for ghe.io configured to use https protocol.
                   ✓ Token: ******
+                  Source: GH_CONFIG_DIR/hosts.yml
                   ✓ Token scopes: repo,read:org
             `),
 		},
@@ -181,15 +239,264 @@ func Test_statusRun(t *testing.T) {
                   ✓ Logged in to github.com as monalisa (GH_CONFIG_DIR/hosts.yml)
                   ✓ Git operations for github.com configured to use https protocol.
                   ✓ Token: gho_******
+                  Source: GH_CONFIG_DIR/hosts.yml
                   ✓ Token scopes: repo, read:org
 
                 ghe.io
                   ✓ Logged in to ghe.io as monalisa-ghe (GH_CONFIG_DIR/hosts.yml)
                   ✓ Git operations for ghe.io configured to use ssh protocol.
                   ✓ Token: gho_******
+                  Source: GH_CONFIG_DIR/hosts.yml
                   ! Token scopes: none
             `),
 		},
+		{
+			name: "all good with --json",
+			opts: StatusOptions{
+				Exporter: jsonExporter(),
+			},
+			cfgStubs: func(c config.Config) {
+				login(t, c, "github.com", "monalisa", "gho_abc123", "https")
+			},
+			httpStubs: func(reg *httpmock.Registry) {
+				reg.Register(
+					httpmock.REST("GET", ""),
+					httpmock.WithHeader(httpmock.ScopesResponder("repo,read:org"), "X-Oauth-Scopes", "repo, read:org"))
+			},
+			wantOut: `[{"active":true,"error":"","gitProtocol":"https","hasMinimumScopes":true,"hostname":"github.com","orgs":null,"scopes":["repo","read:org"],"tokenLastEightChars":"o_abc123","tokenSource":"GH_CONFIG_DIR/hosts.yml","user":"monalisa"}]
+`,
+		},
+		{
+			name: "missing scope with --json does not fail the command",
+			opts: StatusOptions{
+				Exporter: jsonExporter(),
+			},
+			cfgStubs: func(c config.Config) {
+				login(t, c, "ghe.io", "monalisa-ghe", "abc123", "https")
+			},
+			httpStubs: func(reg *httpmock.Registry) {
+				reg.Register(httpmock.REST("GET", "api/v3/"), httpmock.ScopesResponder("repo"))
+			},
+			wantOut: `[{"active":true,"error":"missing required scope 'read:org'","gitProtocol":"https","hasMinimumScopes":false,"hostname":"ghe.io","orgs":null,"scopes":["repo"],"tokenLastEightChars":"abc123","tokenSource":"GH_CONFIG_DIR/hosts.yml","user":"monalisa-ghe"}]
+`,
+		},
+		{
+			name: "multiple accounts on a host with --json",
+			opts: StatusOptions{
+				Exporter: jsonExporter(),
+			},
+			cfgStubs: func(c config.Config) {
+				login(t, c, "github.com", "monalisa", "abc123", "https")
+				login(t, c, "github.com", "monalisa-2", "def456", "https")
+			},
+			httpStubs: func(reg *httpmock.Registry) {
+				// The two probes run concurrently, so each stub is matched by the token it was
+				// sent with rather than by the order in which the requests arrive.
+				withToken := func(token string) httpmock.Matcher {
+					return func(req *http.Request) bool {
+						return httpmock.REST("GET", "")(req) && strings.HasSuffix(req.Header.Get("Authorization"), token)
+					}
+				}
+				reg.Register(withToken("def456"), httpmock.ScopesResponder("repo,read:org"))
+				reg.Register(withToken("abc123"), httpmock.ScopesResponder("repo,read:org,project:read"))
+			},
+			wantOut: `[{"active":true,"error":"","gitProtocol":"https","hasMinimumScopes":true,"hostname":"github.com","orgs":null,"scopes":["repo","read:org"],"tokenLastEightChars":"def456","tokenSource":"GH_CONFIG_DIR/hosts.yml","user":"monalisa-2"},{"active":false,"error":"","gitProtocol":"https","hasMinimumScopes":true,"hostname":"github.com","orgs":null,"scopes":["repo","read:org","project:read"],"tokenLastEightChars":"abc123","tokenSource":"GH_CONFIG_DIR/hosts.yml","user":"monalisa"}]
+`,
+		},
+		{
+			name: "org SSO authorized",
+			opts: StatusOptions{
+				Orgs: []string{"acme"},
+			},
+			cfgStubs: func(c config.Config) {
+				login(t, c, "github.com", "monalisa", "gho_abc123", "https")
+			},
+			httpStubs: func(reg *httpmock.Registry) {
+				reg.Register(httpmock.REST("GET", ""), httpmock.ScopesResponder("repo,read:org"))
+				reg.Register(httpmock.REST("GET", "user/orgs"), httpmock.StatusStringResponse(200, `[{"login":"acme"}]`))
+				reg.Register(httpmock.REST("GET", "orgs/acme"), httpmock.StatusStringResponse(200, "{}"))
+			},
+			wantOut: heredoc.Doc(`
+                github.com
+                  ✓ Logged in to github.com as monalisa (GH_CONFIG_DIR/hosts.yml)
+                  ✓ Git operations for github.com configured to use https protocol.
+                  ✓ Token: gho_******
+                  Source: GH_CONFIG_DIR/hosts.yml
+                  ✓ Token scopes: repo,read:org
+                  ✓ SSO authorized for: acme
+            `),
+		},
+		{
+			name: "org status included in --json",
+			opts: StatusOptions{
+				Orgs:     []string{"acme"},
+				Exporter: jsonExporter(),
+			},
+			cfgStubs: func(c config.Config) {
+				login(t, c, "github.com", "monalisa", "gho_abc123", "https")
+			},
+			httpStubs: func(reg *httpmock.Registry) {
+				reg.Register(httpmock.REST("GET", ""), httpmock.ScopesResponder("repo,read:org"))
+				reg.Register(httpmock.REST("GET", "user/orgs"), httpmock.StatusStringResponse(200, `[{"login":"acme"}]`))
+				reg.Register(httpmock.REST("GET", "orgs/acme"), httpmock.StatusStringResponse(200, "{}"))
+			},
+			wantOut: `[{"active":true,"error":"","gitProtocol":"https","hasMinimumScopes":true,"hostname":"github.com","orgs":[{"org":"acme","ssoAuthorized":true,"ssoUrl":"","ipAllowListHit":false,"error":""}],"scopes":["repo","read:org"],"tokenLastEightChars":"o_abc123","tokenSource":"GH_CONFIG_DIR/hosts.yml","user":"monalisa"}]
+`,
+		},
+		{
+			name: "org not a member is skipped",
+			opts: StatusOptions{
+				Orgs: []string{"acme"},
+			},
+			cfgStubs: func(c config.Config) {
+				login(t, c, "github.com", "monalisa", "gho_abc123", "https")
+			},
+			httpStubs: func(reg *httpmock.Registry) {
+				reg.Register(httpmock.REST("GET", ""), httpmock.ScopesResponder("repo,read:org"))
+				reg.Register(httpmock.REST("GET", "user/orgs"), httpmock.StatusStringResponse(200, `[{"login":"contoso"}]`))
+			},
+			wantOut: heredoc.Doc(`
+                github.com
+                  ✓ Logged in to github.com as monalisa (GH_CONFIG_DIR/hosts.yml)
+                  ✓ Git operations for github.com configured to use https protocol.
+                  ✓ Token: gho_******
+                  Source: GH_CONFIG_DIR/hosts.yml
+                  ✓ Token scopes: repo,read:org
+            `),
+		},
+		{
+			name: "org membership check fails",
+			opts: StatusOptions{
+				Orgs: []string{"acme"},
+			},
+			cfgStubs: func(c config.Config) {
+				login(t, c, "github.com", "monalisa", "gho_abc123", "https")
+			},
+			httpStubs: func(reg *httpmock.Registry) {
+				reg.Register(httpmock.REST("GET", ""), httpmock.ScopesResponder("repo,read:org"))
+				reg.Register(httpmock.REST("GET", "user/orgs"), httpmock.StatusStringResponse(500, "oh no"))
+			},
+			wantOut: heredoc.Doc(`
+                github.com
+                  ✓ Logged in to github.com as monalisa (GH_CONFIG_DIR/hosts.yml)
+                  ✓ Git operations for github.com configured to use https protocol.
+                  ✓ Token: gho_******
+                  Source: GH_CONFIG_DIR/hosts.yml
+                  ✓ Token scopes: repo,read:org
+                  X Could not check org status for acme: failed to list organization memberships: 500 Internal Server Error
+            `),
+		},
+		{
+			name: "org requires SSO authorization",
+			opts: StatusOptions{
+				Orgs: []string{"acme"},
+			},
+			cfgStubs: func(c config.Config) {
+				login(t, c, "github.com", "monalisa", "gho_abc123", "https")
+			},
+			httpStubs: func(reg *httpmock.Registry) {
+				reg.Register(httpmock.REST("GET", ""), httpmock.ScopesResponder("repo,read:org"))
+				reg.Register(httpmock.REST("GET", "user/orgs"), httpmock.StatusStringResponse(200, `[{"login":"acme"}]`))
+				reg.Register(httpmock.REST("GET", "orgs/acme"),
+					httpmock.WithHeader(httpmock.StatusStringResponse(200, "{}"), "X-Github-Sso", "required; url=https://github.com/orgs/acme/sso?authorization_request=abc"))
+			},
+			wantOut: heredoc.Doc(`
+                github.com
+                  ✓ Logged in to github.com as monalisa (GH_CONFIG_DIR/hosts.yml)
+                  ✓ Git operations for github.com configured to use https protocol.
+                  ✓ Token: gho_******
+                  Source: GH_CONFIG_DIR/hosts.yml
+                  ✓ Token scopes: repo,read:org
+                  X Token needs SSO authorization for acme (run: gh auth refresh -h github.com then visit https://github.com/orgs/acme/sso?authorization_request=abc)
+            `),
+		},
+		{
+			name: "org probe fails with a non-SSO error status",
+			opts: StatusOptions{
+				Orgs: []string{"acme"},
+			},
+			cfgStubs: func(c config.Config) {
+				login(t, c, "github.com", "monalisa", "gho_abc123", "https")
+			},
+			httpStubs: func(reg *httpmock.Registry) {
+				reg.Register(httpmock.REST("GET", ""), httpmock.ScopesResponder("repo,read:org"))
+				reg.Register(httpmock.REST("GET", "user/orgs"), httpmock.StatusStringResponse(200, `[{"login":"acme"}]`))
+				reg.Register(httpmock.REST("GET", "orgs/acme"), httpmock.StatusStringResponse(502, "bad gateway"))
+			},
+			wantOut: heredoc.Doc(`
+                github.com
+                  ✓ Logged in to github.com as monalisa (GH_CONFIG_DIR/hosts.yml)
+                  ✓ Git operations for github.com configured to use https protocol.
+                  ✓ Token: gho_******
+                  Source: GH_CONFIG_DIR/hosts.yml
+                  ✓ Token scopes: repo,read:org
+                  X Could not check org status for acme: failed to check org status: 502 Bad Gateway
+            `),
+		},
+		{
+			name: "org denies request via IP allow list",
+			opts: StatusOptions{
+				Orgs: []string{"acme"},
+			},
+			cfgStubs: func(c config.Config) {
+				login(t, c, "github.com", "monalisa", "gho_abc123", "https")
+			},
+			httpStubs: func(reg *httpmock.Registry) {
+				reg.Register(httpmock.REST("GET", ""), httpmock.ScopesResponder("repo,read:org"))
+				reg.Register(httpmock.REST("GET", "user/orgs"), httpmock.StatusStringResponse(200, `[{"login":"acme"}]`))
+				reg.Register(httpmock.REST("GET", "orgs/acme"),
+					httpmock.StatusStringResponse(403, `{"message":"organization has enabled or enforced IP allow list"}`))
+			},
+			wantOut: heredoc.Doc(`
+                github.com
+                  ✓ Logged in to github.com as monalisa (GH_CONFIG_DIR/hosts.yml)
+                  ✓ Git operations for github.com configured to use https protocol.
+                  ✓ Token: gho_******
+                  Source: GH_CONFIG_DIR/hosts.yml
+                  ✓ Token scopes: repo,read:org
+                  X Request denied by IP allow list
+            `),
+		},
+		{
+			name: "--sources prints the credential resolution chain",
+			opts: StatusOptions{
+				Sources: true,
+			},
+			env: map[string]string{
+				"GH_TOKEN": "envtoken123",
+			},
+			cfgStubs: func(c config.Config) {
+				login(t, c, "github.com", "monalisa", "gho_abc123", "https")
+			},
+			httpStubs: func(reg *httpmock.Registry) {
+				reg.Register(httpmock.REST("GET", ""), httpmock.ScopesResponder("repo,read:org"))
+			},
+			wantOut: heredoc.Doc(`
+                github.com
+                  ✓ Logged in to github.com as monalisa (GH_TOKEN env)
+                  ✓ Git operations for github.com configured to use https protocol.
+                  ✓ Token: ******
+                  Source: GH_TOKEN env
+                  ✓ Token scopes: repo,read:org
+                  Credential sources for monalisa:
+                    ✓ GH_TOKEN env (available)
+                      GH_TOKEN_FILE (not available)
+                      keyring (available)
+                      GH_CONFIG_DIR/hosts.yml (available)
+            `),
+		},
+		{
+			name: "--sources errors when combined with --json",
+			opts: StatusOptions{
+				Sources:  true,
+				Exporter: jsonExporter(),
+			},
+			cfgStubs: func(c config.Config) {
+				login(t, c, "github.com", "monalisa", "gho_abc123", "https")
+			},
+			httpStubs: func(reg *httpmock.Registry) {},
+			wantErr:   cmdutil.FlagErrorf("`--sources` does not support `--json`; the per-account \"Source:\" field already carries the winning source"),
+		},
 		{
 			name: "server-to-server token",
 			opts: StatusOptions{},
@@ -207,6 +514,7 @@ func Test_statusRun(t *testing.T) {
                   ✓ Logged in to github.com as monalisa (GH_CONFIG_DIR/hosts.yml)
                   ✓ Git operations for github.com configured to use https protocol.
                   ✓ Token: ghs_******
+                  Source: GH_CONFIG_DIR/hosts.yml
             `),
 		},
 		{
@@ -226,6 +534,7 @@ func Test_statusRun(t *testing.T) {
                   ✓ Logged in to github.com as monalisa (GH_CONFIG_DIR/hosts.yml)
                   ✓ Git operations for github.com configured to use https protocol.
                   ✓ Token: github_pat_******
+                  Source: GH_CONFIG_DIR/hosts.yml
             `),
 		},
 		{
@@ -248,12 +557,14 @@ func Test_statusRun(t *testing.T) {
                   ✓ Logged in to github.com as monalisa (GH_CONFIG_DIR/hosts.yml)
                   ✓ Git operations for github.com configured to use https protocol.
                   ✓ Token: abc123
+                  Source: GH_CONFIG_DIR/hosts.yml
                   ✓ Token scopes: repo,read:org
 
                 ghe.io
                   ✓ Logged in to ghe.io as monalisa-ghe (GH_CONFIG_DIR/hosts.yml)
                   ✓ Git operations for ghe.io configured to use https protocol.
                   ✓ Token: xyz456
+                  Source: GH_CONFIG_DIR/hosts.yml
                   ✓ Token scopes: repo,read:org
             `),
 		},
@@ -282,14 +593,16 @@ func Test_statusRun(t *testing.T) {
 			},
 			wantOut: heredoc.Doc(`
                 github.com
-                  ✓ Logged in to github.com as monalisa-2 (GH_CONFIG_DIR/hosts.yml)
+                  ✓ Logged in to github.com as monalisa-2 (GH_CONFIG_DIR/hosts.yml) (active)
                   ✓ Git operations for github.com configured to use https protocol.
                   ✓ Token: ******
+                  Source: GH_CONFIG_DIR/hosts.yml
                   ✓ Token scopes: repo,read:org
 
                   ✓ Logged in to github.com as monalisa (GH_CONFIG_DIR/hosts.yml)
                   ✓ Git operations for github.com configured to use https protocol.
                   ✓ Token: ******
+                  Source: GH_CONFIG_DIR/hosts.yml
                   ✓ Token scopes: repo,read:org,project:read
             `),
 		},
@@ -299,6 +612,10 @@ func Test_statusRun(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			keyring.MockInit()
 
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
 			ios, _, stdout, stderr := iostreams.Test()
 
 			ios.SetStdinTTY(true)
@@ -337,6 +654,12 @@ func Test_statusRun(t *testing.T) {
 	}
 }
 
+func jsonExporter() cmdutil.Exporter {
+	exporter := cmdutil.NewJSONExporter()
+	exporter.SetFields(jsonFields)
+	return exporter
+}
+
 func login(t *testing.T, c config.Config, hostname, username, protocol, token string) {
 	t.Helper()
 	_, err := c.Authentication().Login(hostname, username, protocol, token, false)
@@ -0,0 +1,122 @@
+package switchacc
+
+import (
+	"fmt"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cli/cli/v2/internal/config"
+	"github.com/cli/cli/v2/internal/prompter"
+	"github.com/cli/cli/v2/pkg/cmdutil"
+	"github.com/cli/cli/v2/pkg/iostreams"
+	"github.com/spf13/cobra"
+)
+
+type SwitchOptions struct {
+	IO       *iostreams.IOStreams
+	Config   func() (config.Config, error)
+	Prompter prompter.Prompter
+
+	Hostname string
+	Username string
+}
+
+func NewCmdSwitch(f *cmdutil.Factory, runF func(*SwitchOptions) error) *cobra.Command {
+	opts := &SwitchOptions{
+		IO:       f.IOStreams,
+		Config:   f.Config,
+		Prompter: f.Prompter,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "switch",
+		Args:  cobra.ExactArgs(0),
+		Short: "Change the active account for a host",
+		Long: heredoc.Docf(`Switch the active account for a host to one of the accounts you're already logged
+			in as.
+
+			The active account for a host is the one used automatically by other gh commands and by
+			the git credential helper. Use %[1]sgh auth status%[1]s to see every account currently
+			logged in and which one is active.
+		`, "`"),
+		Example: heredoc.Doc(`
+			# Interactively select an account to switch to
+			$ gh auth switch
+
+			# Switch the active account on a specific host
+			$ gh auth switch --hostname enterprise.internal --user monalisa
+		`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if runF != nil {
+				return runF(opts)
+			}
+
+			return switchRun(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Hostname, "hostname", "h", "", "The hostname of the GitHub instance to switch account for")
+	cmd.Flags().StringVarP(&opts.Username, "user", "u", "", "The account to switch to")
+
+	return cmd
+}
+
+func switchRun(opts *SwitchOptions) error {
+	cfg, err := opts.Config()
+	if err != nil {
+		return err
+	}
+	authCfg := cfg.Authentication()
+
+	hostname := opts.Hostname
+	if hostname == "" {
+		candidates := authCfg.HostsWithMultipleUsers()
+		switch len(candidates) {
+		case 0:
+			return fmt.Errorf("no hosts have more than one logged in account")
+		case 1:
+			hostname = candidates[0]
+		default:
+			if !opts.IO.CanPrompt() {
+				return fmt.Errorf("more than one host has multiple logged in accounts, please specify --hostname")
+			}
+			hostname, err = opts.Prompter.Select("What account on which host do you want to switch to?", "", candidates)
+			if err != nil {
+				return fmt.Errorf("could not prompt: %w", err)
+			}
+		}
+	}
+
+	username := opts.Username
+	if username == "" {
+		users := authCfg.UsersForHost(hostname)
+		switch len(users) {
+		case 0:
+			return fmt.Errorf("not logged in to any accounts on %s", hostname)
+		case 1:
+			return fmt.Errorf("only one account logged in to %s, nothing to switch to", hostname)
+		default:
+			if !opts.IO.CanPrompt() {
+				return fmt.Errorf("please specify --user")
+			}
+			username, err = opts.Prompter.Select(fmt.Sprintf("Switch active account for %s to:", hostname), "", users)
+			if err != nil {
+				return fmt.Errorf("could not prompt: %w", err)
+			}
+		}
+	}
+
+	previousUser := authCfg.ActiveUser(hostname)
+	if previousUser == username {
+		fmt.Fprintf(opts.IO.ErrOut, "%s %s is already the active account for %s\n", opts.IO.ColorScheme().SuccessIcon(), username, hostname)
+		return nil
+	}
+
+	if err := authCfg.SwitchUser(hostname, username); err != nil {
+		return fmt.Errorf("failed to switch active account for %s to %s: %w", hostname, username, err)
+	}
+
+	cs := opts.IO.ColorScheme()
+	fmt.Fprintf(opts.IO.ErrOut, "%s Switched active account for %s from %s to %s\n", cs.SuccessIcon(), hostname, previousUser, username)
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package switchacc
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cli/cli/v2/internal/config"
+	"github.com/cli/cli/v2/internal/prompter"
+	"github.com/cli/cli/v2/pkg/cmdutil"
+	"github.com/cli/cli/v2/pkg/iostreams"
+	"github.com/google/shlex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewCmdSwitch(t *testing.T) {
+	tests := []struct {
+		name  string
+		cli   string
+		wants SwitchOptions
+	}{
+		{
+			name:  "no arguments",
+			cli:   "",
+			wants: SwitchOptions{},
+		},
+		{
+			name: "hostname and user set",
+			cli:  "--hostname ellie.williams --user monalisa",
+			wants: SwitchOptions{
+				Hostname: "ellie.williams",
+				Username: "monalisa",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &cmdutil.Factory{}
+
+			argv, err := shlex.Split(tt.cli)
+			assert.NoError(t, err)
+
+			var gotOpts *SwitchOptions
+			cmd := NewCmdSwitch(f, func(opts *SwitchOptions) error {
+				gotOpts = opts
+				return nil
+			})
+
+			cmd.Flags().BoolP("help", "x", false, "")
+
+			cmd.SetArgs(argv)
+			cmd.SetIn(&bytes.Buffer{})
+			cmd.SetOut(&bytes.Buffer{})
+			cmd.SetErr(&bytes.Buffer{})
+
+			_, err = cmd.ExecuteC()
+			assert.NoError(t, err)
+
+			assert.Equal(t, tt.wants.Hostname, gotOpts.Hostname)
+			assert.Equal(t, tt.wants.Username, gotOpts.Username)
+		})
+	}
+}
+
+func Test_switchRun(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       SwitchOptions
+		cfgStubs   func(config.Config)
+		prompter   func(*prompter.MockPrompter)
+		wantErr    string
+		wantErrOut string
+	}{
+		{
+			name: "switches active account",
+			opts: SwitchOptions{
+				Hostname: "github.com",
+				Username: "monalisa",
+			},
+			cfgStubs: func(c config.Config) {
+				login(t, c, "github.com", "monalisa", "abc123", "https")
+				login(t, c, "github.com", "monalisa-2", "def456", "https")
+			},
+			wantErrOut: "✓ Switched active account for github.com from monalisa-2 to monalisa\n",
+		},
+		{
+			name: "already the active account",
+			opts: SwitchOptions{
+				Hostname: "github.com",
+				Username: "monalisa-2",
+			},
+			cfgStubs: func(c config.Config) {
+				login(t, c, "github.com", "monalisa", "abc123", "https")
+				login(t, c, "github.com", "monalisa-2", "def456", "https")
+			},
+			wantErrOut: "✓ monalisa-2 is already the active account for github.com\n",
+		},
+		{
+			name: "errors when only one account is logged in",
+			opts: SwitchOptions{
+				Hostname: "github.com",
+			},
+			cfgStubs: func(c config.Config) {
+				login(t, c, "github.com", "monalisa", "abc123", "https")
+			},
+			wantErr: "only one account logged in to github.com, nothing to switch to",
+		},
+		{
+			name: "errors when no hosts have multiple accounts",
+			opts: SwitchOptions{},
+			cfgStubs: func(c config.Config) {
+				login(t, c, "github.com", "monalisa", "abc123", "https")
+			},
+			wantErr: "no hosts have more than one logged in account",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ios, _, _, stderr := iostreams.Test()
+			ios.SetStdinTTY(true)
+			ios.SetStderrTTY(true)
+			ios.SetStdoutTTY(true)
+			tt.opts.IO = ios
+
+			cfg, _ := config.NewIsolatedTestConfig(t)
+			if tt.cfgStubs != nil {
+				tt.cfgStubs(cfg)
+			}
+			tt.opts.Config = func() (config.Config, error) {
+				return cfg, nil
+			}
+
+			pm := prompter.NewMockPrompter(t)
+			if tt.prompter != nil {
+				tt.prompter(pm)
+			}
+			tt.opts.Prompter = pm
+
+			err := switchRun(&tt.opts)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantErrOut, stderr.String())
+		})
+	}
+}
+
+func login(t *testing.T, c config.Config, hostname, username, token, protocol string) {
+	t.Helper()
+	_, err := c.Authentication().Login(hostname, username, token, protocol, false)
+	require.NoError(t, err)
+}